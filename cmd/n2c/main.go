@@ -3,24 +3,135 @@ package main
 import (
 	"context"
 	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/lucifer/ntfy2clip/internal/config"
+	"github.com/lucifer/ntfy2clip/internal/metrics"
 	"github.com/lucifer/ntfy2clip/internal/websocket"
 )
 
+const (
+	backoffBase = 1 * time.Second
+	backoffCap  = 5 * time.Minute
+
+	// maxConsecutiveFatalErrors is how many consecutive 4xx subscribe
+	// rejections (auth/topic errors that won't fix themselves) we tolerate
+	// before giving up, so systemd/docker restart policies can decide
+	// whether to keep retrying.
+	maxConsecutiveFatalErrors = 5
+	fatalExitCode             = 2
+)
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Configuration error: %v", err)
 	}
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	topics := make([]string, len(cfg.Topics))
+	for i, sub := range cfg.Topics {
+		topics[i] = sub.Topic
+	}
+	reg := metrics.New(topics, cfg.Timeout)
+
+	if cfg.MetricsAddr != "" {
+		server := &http.Server{Addr: cfg.MetricsAddr, Handler: reg.Mux()}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Printf("metrics server error: %v", err)
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			server.Close()
+		}()
+	}
+
+	var wg sync.WaitGroup
+	var givenUp atomic.Int32
+	for _, sub := range cfg.Topics {
+		wg.Add(1)
+		go func(sub config.TopicSubscription) {
+			defer wg.Done()
+			runSubscription(ctx, cfg.ForTopic(sub), reg, &givenUp)
+		}(sub)
+	}
+	wg.Wait()
 
-	for {
-		if err := websocket.RunConnection(ctx, cfg); err != nil {
-			log.Printf("Connection error: %v. Reconnecting...", err)
-			time.Sleep(5 * time.Second)
+	// Only exit non-zero if every subscription gave up; if at least one
+	// topic is still being served, a single misconfigured/revoked topic
+	// shouldn't take the whole process down.
+	if ctx.Err() == nil && int(givenUp.Load()) == len(cfg.Topics) {
+		os.Exit(fatalExitCode)
+	}
+}
+
+// runSubscription reconnects connCfg forever, with its own backoff and
+// circuit-breaker state, until ctx is cancelled. If connCfg's subscription
+// hits maxConsecutiveFatalErrors, it gives up and returns (incrementing
+// givenUp) rather than taking down the other subscriptions.
+func runSubscription(ctx context.Context, connCfg *config.Config, reg *metrics.Registry, givenUp *atomic.Int32) {
+	attempt := 0
+	consecutiveFatal := 0
+
+	for ctx.Err() == nil {
+		connectedAt := time.Now()
+		err := websocket.RunConnection(ctx, connCfg, reg)
+		if ctx.Err() != nil {
+			return
 		}
+
+		if websocket.IsFatal(err) {
+			consecutiveFatal++
+			if consecutiveFatal >= maxConsecutiveFatalErrors {
+				log.Printf("[%s] Giving up after %d consecutive auth/topic errors: %v", connCfg.Topic, consecutiveFatal, err)
+				reg.Abandon(connCfg.Topic)
+				givenUp.Add(1)
+				return
+			}
+		} else {
+			consecutiveFatal = 0
+		}
+
+		if time.Since(connectedAt) > connCfg.Timeout*2 {
+			attempt = 0
+		}
+
+		reg.Reconnect()
+
+		sleep := fullJitterBackoff(attempt)
+		log.Printf("[%s] Connection error: %v. Reconnecting in %v...", connCfg.Topic, err, sleep)
+
+		select {
+		case <-time.After(sleep):
+		case <-ctx.Done():
+			return
+		}
+		attempt++
 	}
 }
+
+// fullJitterBackoff implements Amazon's "Full Jitter" algorithm:
+// sleep = random(0, min(cap, base * 2^attempt)).
+func fullJitterBackoff(attempt int) time.Duration {
+	if attempt > 30 {
+		attempt = 30 // avoid overflow; 2^30 * base already dwarfs backoffCap
+	}
+
+	max := backoffBase * time.Duration(int64(1)<<uint(attempt))
+	if max <= 0 || max > backoffCap {
+		max = backoffCap
+	}
+
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestFullJitterBackoffBounds(t *testing.T) {
+	for attempt := 0; attempt <= 40; attempt++ {
+		sleep := fullJitterBackoff(attempt)
+		if sleep < 0 {
+			t.Fatalf("fullJitterBackoff(%d) = %v, want >= 0", attempt, sleep)
+		}
+		if sleep > backoffCap {
+			t.Fatalf("fullJitterBackoff(%d) = %v, want <= backoffCap (%v)", attempt, sleep, backoffCap)
+		}
+	}
+}
+
+func TestFullJitterBackoffCapsLargeAttempts(t *testing.T) {
+	// Attempts past 30 would overflow the 2^attempt term if not clamped;
+	// this only asserts it stays within backoffCap rather than panicking
+	// or going negative.
+	for _, attempt := range []int{31, 62, 1000} {
+		sleep := fullJitterBackoff(attempt)
+		if sleep < 0 || sleep > backoffCap {
+			t.Fatalf("fullJitterBackoff(%d) = %v, want in [0, backoffCap]", attempt, sleep)
+		}
+	}
+}
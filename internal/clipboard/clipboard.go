@@ -6,18 +6,35 @@ import (
 	"os/exec"
 )
 
-func Set(content string) error {
-	log.Printf("Setting clipboard to: %s", content)
+// Content is a clipboard payload paired with its MIME type, so that
+// binary attachments (e.g. ntfy images) can be routed to the right
+// platform clipboard format instead of being treated as plain text.
+type Content struct {
+	MIMEType string
+	Data     []byte
+}
+
+// Text wraps a plain string as a text/plain Content value, for the
+// common case of copying a message body.
+func Text(s string) Content {
+	return Content{MIMEType: "text/plain", Data: []byte(s)}
+}
 
-	cmd, envName, err := getCommand()
-	if err != nil {
-		return err
-	}
+// Set copies content to the system clipboard using the platform backend
+// implemented in clipboard_<os>.go.
+func Set(content Content) error {
+	log.Printf("Setting clipboard to %d bytes of %s", len(content.Data), content.MIMEType)
+	return setContent(content)
+}
 
+// runCommand execs cmd with content piped to stdin. Shared by the
+// shell-based backends (Linux, macOS, FreeBSD); Windows talks to the
+// clipboard natively instead.
+func runCommand(cmd []string, envName string, content Content) error {
 	log.Printf("Running under %s, using copy command %s", envName, cmd[0])
 
 	c := exec.Command(cmd[0], cmd[1:]...)
-	c.Stdin = bytes.NewBufferString(content)
+	c.Stdin = bytes.NewBuffer(content.Data)
 
 	return c.Run()
 }
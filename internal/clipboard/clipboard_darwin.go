@@ -0,0 +1,58 @@
+//go:build darwin
+
+package clipboard
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func setContent(content Content) error {
+	if content.MIMEType != "" && content.MIMEType != "text/plain" {
+		return setImage(content)
+	}
+	return runCommand([]string{"/usr/bin/pbcopy"}, "macOS", content)
+}
+
+// appleScriptImageClass maps the MIME subtype of an image attachment to
+// the AppleScript four-char class osascript needs to tag it with on the
+// pasteboard; the class must match the bytes or osascript errors instead
+// of producing a usable image.
+var appleScriptImageClass = map[string]string{
+	"png":  "PNGf",
+	"jpeg": "JPEG",
+	"jpg":  "JPEG",
+	"gif":  "GIFf",
+	"tiff": "TIFF",
+	"tif":  "TIFF",
+	"bmp":  "BMP ",
+	"heic": "heic",
+}
+
+// setImage writes the payload to a temp file and hands it to osascript,
+// since pbcopy only ever accepts plain text on stdin.
+func setImage(content Content) error {
+	subtype := strings.TrimPrefix(content.MIMEType, "image/")
+	class, ok := appleScriptImageClass[strings.ToLower(subtype)]
+	if !ok {
+		return fmt.Errorf("unsupported image MIME type for macOS clipboard: %s", content.MIMEType)
+	}
+
+	tmp, err := os.CreateTemp("", "n2c-clip-*.img")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content.Data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(`set the clipboard to (read (POSIX file %q) as «class %s»)`, tmp.Name(), class)
+	return runCommand([]string{"/usr/bin/osascript", "-e", script}, "macOS", Content{})
+}
@@ -0,0 +1,34 @@
+//go:build freebsd
+
+package clipboard
+
+import (
+	"errors"
+	"os"
+)
+
+func setContent(content Content) error {
+	cmd, envName, err := getCommand(content.MIMEType)
+	if err != nil {
+		return err
+	}
+	return runCommand(cmd, envName, content)
+}
+
+func getCommand(mimeType string) ([]string, string, error) {
+	isImage := mimeType != "" && mimeType != "text/plain"
+
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if isImage {
+			return []string{"/usr/local/bin/wl-copy", "--type", mimeType}, "Wayland", nil
+		}
+		return []string{"/usr/local/bin/wl-copy"}, "Wayland", nil
+	}
+	if os.Getenv("DISPLAY") != "" {
+		if isImage {
+			return []string{"/usr/local/bin/xclip", "-selection", "clipboard", "-t", mimeType}, "Xorg", nil
+		}
+		return []string{"/usr/local/bin/xclip", "-sel", "clip", "-r", "-in"}, "Xorg", nil
+	}
+	return nil, "", errors.New("unsupported FreeBSD environment (no WAYLAND_DISPLAY or DISPLAY)")
+}
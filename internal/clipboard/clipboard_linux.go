@@ -7,14 +7,30 @@ import (
 	"os"
 )
 
-func getCommand() ([]string, string, error) {
+func setContent(content Content) error {
+	cmd, envName, err := getCommand(content.MIMEType)
+	if err != nil {
+		return err
+	}
+	return runCommand(cmd, envName, content)
+}
+
+func getCommand(mimeType string) ([]string, string, error) {
+	isImage := mimeType != "" && mimeType != "text/plain"
+
 	if os.Getenv("WSL_DISTRO_NAME") != "" {
 		return []string{"/mnt/c/Windows/System32/clip.exe"}, "WSL", nil
 	}
 	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if isImage {
+			return []string{"/usr/bin/wl-copy", "--type", mimeType}, "Wayland", nil
+		}
 		return []string{"/usr/bin/wl-copy"}, "Wayland", nil
 	}
 	if os.Getenv("DISPLAY") != "" {
+		if isImage {
+			return []string{"/usr/bin/xclip", "-selection", "clipboard", "-t", mimeType}, "Xorg", nil
+		}
 		return []string{"/usr/bin/xclip", "-sel", "clip", "-r", "-in"}, "Xorg", nil
 	}
 	return nil, "", errors.New("unsupported Linux environment (no WAYLAND_DISPLAY or DISPLAY)")
@@ -0,0 +1,118 @@
+//go:build windows
+
+package clipboard
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	user32               = syscall.NewLazyDLL("user32.dll")
+	kernel32             = syscall.NewLazyDLL("kernel32.dll")
+	procOpenClipboard    = user32.NewProc("OpenClipboard")
+	procCloseClipboard   = user32.NewProc("CloseClipboard")
+	procEmptyClipboard   = user32.NewProc("EmptyClipboard")
+	procSetClipboardData = user32.NewProc("SetClipboardData")
+	procGlobalAlloc      = kernel32.NewProc("GlobalAlloc")
+	procGlobalLock       = kernel32.NewProc("GlobalLock")
+	procGlobalUnlock     = kernel32.NewProc("GlobalUnlock")
+	procGlobalFree       = kernel32.NewProc("GlobalFree")
+	procMoveMemory       = kernel32.NewProc("RtlMoveMemory")
+)
+
+const (
+	cfUnicodeText = 13
+	gmemMoveable  = 0x0002
+)
+
+func setContent(content Content) error {
+	if content.MIMEType != "" && content.MIMEType != "text/plain" {
+		return setImage(content)
+	}
+	if err := setText(string(content.Data)); err != nil {
+		log.Printf("native clipboard write failed, falling back to PowerShell: %v", err)
+		return setViaPowershell(content)
+	}
+	return nil
+}
+
+// setText writes UTF-16 text to the clipboard via the native Win32 API,
+// avoiding a PowerShell subprocess for the common case.
+func setText(text string) error {
+	utf16, err := syscall.UTF16FromString(text)
+	if err != nil {
+		return err
+	}
+
+	if r, _, err := procOpenClipboard.Call(0); r == 0 {
+		return fmt.Errorf("OpenClipboard failed: %w", err)
+	}
+	defer procCloseClipboard.Call()
+
+	procEmptyClipboard.Call()
+
+	size := len(utf16) * 2
+	h, _, err := procGlobalAlloc.Call(gmemMoveable, uintptr(size))
+	if h == 0 {
+		return fmt.Errorf("GlobalAlloc failed: %w", err)
+	}
+
+	ptr, _, err := procGlobalLock.Call(h)
+	if ptr == 0 {
+		procGlobalFree.Call(h)
+		return fmt.Errorf("GlobalLock failed: %w", err)
+	}
+	// RtlMoveMemory copies between two uintptr addresses, so the OS-owned
+	// ptr never needs converting to a Go unsafe.Pointer; only the source
+	// slice's own address crosses that boundary, which is always safe.
+	procMoveMemory.Call(ptr, uintptr(unsafe.Pointer(&utf16[0])), uintptr(size))
+	procGlobalUnlock.Call(h)
+
+	if r, _, err := procSetClipboardData.Call(cfUnicodeText, h); r == 0 {
+		// SetClipboardData failed, so the clipboard never took ownership
+		// of h; we're still on the hook for freeing it.
+		procGlobalFree.Call(h)
+		return fmt.Errorf("SetClipboardData failed: %w", err)
+	}
+
+	return nil
+}
+
+// setViaPowershell is the text-path fallback for hosts where the native
+// clipboard call fails (e.g. another process holding the clipboard open).
+func setViaPowershell(content Content) error {
+	c := exec.Command("powershell", "-NoProfile", "-Command", "$input | Set-Clipboard")
+	c.Stdin = bytes.NewReader(content.Data)
+	return c.Run()
+}
+
+// setImage writes the payload to a temp file and loads it through
+// System.Drawing/Windows.Forms, since piping raw bytes into Set-Clipboard
+// treats stdin as newline-delimited text and shreds binary image data.
+func setImage(content Content) error {
+	tmp, err := os.CreateTemp("", "n2c-clip-*.img")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content.Data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; `+
+		`Add-Type -AssemblyName System.Drawing; `+
+		`$img = [System.Drawing.Image]::FromFile('%s'); `+
+		`[System.Windows.Forms.Clipboard]::SetImage($img)`, tmp.Name())
+	return exec.Command("powershell", "-NoProfile", "-Command", script).Run()
+}
@@ -3,27 +3,100 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
+	"text/template"
 	"time"
 )
 
+// TopicSubscription identifies a single (server, topic) pair to
+// subscribe to, with its own auth token.
+type TopicSubscription struct {
+	Server string
+	Topic  string
+	Token  string
+}
+
 type Config struct {
-	Server  string
-	Scheme  string
-	Topic   string
-	Token   string
-	Timeout time.Duration
+	Server      string
+	Scheme      string
+	Topic       string
+	Token       string
+	Timeout     time.Duration
+	Transport   string
+	PriorityMin int
+	TagFilter   []string
+	TitleRegex  *regexp.Regexp
+	Template    *template.Template
+
+	// Topics lists every (server, topic) pair main should subscribe to.
+	// When len(Topics) == 1 this mirrors Server/Topic/Token above.
+	Topics []TopicSubscription
+
+	// PrefixClipboardWithTopic prepends "[topic] " to copied text so
+	// the user knows which subscription produced it. Attachments copied
+	// under their own MIME type are not prefixed.
+	PrefixClipboardWithTopic bool
+
+	// MetricsAddr, if set, is the address a Prometheus /metrics and
+	// /healthz HTTP server listens on (e.g. ":9090").
+	MetricsAddr string
+}
+
+// ForTopic returns a copy of cfg scoped to a single subscription, ready
+// to pass to websocket.RunConnection.
+func (c *Config) ForTopic(sub TopicSubscription) *Config {
+	cp := *c
+	cp.Server = sub.Server
+	cp.Topic = sub.Topic
+	cp.Token = sub.Token
+	return &cp
 }
 
 func Load() (*Config, error) {
-	topic := os.Getenv("TOPIC")
-	if topic == "" {
-		return nil, fmt.Errorf("TOPIC environment variable is required")
+	topicsEnv := os.Getenv("TOPICS")
+	if topicsEnv == "" {
+		topicsEnv = os.Getenv("TOPIC")
+	}
+	if topicsEnv == "" {
+		return nil, fmt.Errorf("TOPICS (or TOPIC) environment variable is required")
+	}
+	topics := splitCSV(topicsEnv)
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("TOPICS (or TOPIC) must contain at least one non-empty topic")
 	}
 
-	server := os.Getenv("SERVER")
-	if server == "" {
-		server = "ntfy.sh"
+	var servers []string
+	if serversEnv := os.Getenv("SERVERS"); serversEnv != "" {
+		servers = splitCSV(serversEnv)
+	} else if server := os.Getenv("SERVER"); server != "" {
+		servers = []string{server}
+	}
+
+	defaultToken := os.Getenv("TOKEN")
+
+	subs := make([]TopicSubscription, len(topics))
+	for i, topic := range topics {
+		server := "ntfy.sh"
+		switch len(servers) {
+		case 0:
+			// use the default above
+		case 1:
+			server = servers[0]
+		default:
+			if len(servers) != len(topics) {
+				return nil, fmt.Errorf("SERVERS must list exactly one server or one per topic (%d topics, %d servers)", len(topics), len(servers))
+			}
+			server = servers[i]
+		}
+
+		token := defaultToken
+		if v := os.Getenv("TOKEN_" + envKey(topic)); v != "" {
+			token = v
+		}
+
+		subs[i] = TopicSubscription{Server: server, Topic: topic, Token: token}
 	}
 
 	scheme := os.Getenv("SCHEME")
@@ -31,8 +104,6 @@ func Load() (*Config, error) {
 		scheme = "wss"
 	}
 
-	token := os.Getenv("TOKEN")
-
 	timeoutSec := 120
 	if timeoutStr := os.Getenv("TIMEOUT"); timeoutStr != "" {
 		if t, err := strconv.Atoi(timeoutStr); err == nil && t > 0 {
@@ -40,11 +111,104 @@ func Load() (*Config, error) {
 		}
 	}
 
+	transport := os.Getenv("TRANSPORT")
+	switch transport {
+	case "", "ws", "json", "sse":
+		// valid, "" defaults to ws below
+	default:
+		return nil, fmt.Errorf("invalid TRANSPORT %q: must be one of ws, json, sse", transport)
+	}
+	if transport == "" {
+		transport = "ws"
+	}
+
+	priorityMin := 0
+	if v := os.Getenv("PRIORITY_MIN"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PRIORITY_MIN %q: %w", v, err)
+		}
+		priorityMin = p
+	}
+
+	var tagFilter []string
+	if v := os.Getenv("TAG_FILTER"); v != "" {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tagFilter = append(tagFilter, tag)
+			}
+		}
+	}
+
+	var titleRegex *regexp.Regexp
+	if v := os.Getenv("TITLE_REGEX"); v != "" {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TITLE_REGEX %q: %w", v, err)
+		}
+		titleRegex = re
+	}
+
+	var tmpl *template.Template
+	if v := os.Getenv("TEMPLATE"); v != "" {
+		t, err := template.New("TEMPLATE").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TEMPLATE %q: %w", v, err)
+		}
+		tmpl = t
+	}
+
+	prefixClipboard := false
+	if v := os.Getenv("TOPIC_PREFIX_CLIPBOARD"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOPIC_PREFIX_CLIPBOARD %q: %w", v, err)
+		}
+		prefixClipboard = b
+	}
+
+	first := subs[0]
 	return &Config{
-		Server:  server,
-		Scheme:  scheme,
-		Topic:   topic,
-		Token:   token,
-		Timeout: time.Duration(timeoutSec) * time.Second,
+		Server:                   first.Server,
+		Scheme:                   scheme,
+		Topic:                    first.Topic,
+		Token:                    first.Token,
+		Timeout:                  time.Duration(timeoutSec) * time.Second,
+		Transport:                transport,
+		PriorityMin:              priorityMin,
+		TagFilter:                tagFilter,
+		TitleRegex:               titleRegex,
+		Template:                 tmpl,
+		Topics:                   subs,
+		PrefixClipboardWithTopic: prefixClipboard,
+		MetricsAddr:              os.Getenv("METRICS_ADDR"),
 	}, nil
 }
+
+// splitCSV splits a comma-separated env var value, trimming whitespace
+// and dropping empty entries.
+func splitCSV(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// envKey turns a topic name into the suffix used by its TOKEN_<topic>
+// override, since topic names may contain characters that aren't valid
+// in environment variable names.
+func envKey(topic string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - ('a' - 'A')
+		case (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9'):
+			return r
+		default:
+			return '_'
+		}
+	}, topic)
+}
@@ -0,0 +1,64 @@
+package config
+
+import "testing"
+
+func TestSplitCSV(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"single", "foo", []string{"foo"}},
+		{"multiple", "foo,bar", []string{"foo", "bar"}},
+		{"whitespace", " foo , bar ", []string{"foo", "bar"}},
+		{"blank entries only", " , , ", nil},
+		{"blank entries mixed with values", "foo,,bar", []string{"foo", "bar"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := splitCSV(tc.in)
+			if len(got) != len(tc.want) {
+				t.Fatalf("splitCSV(%q) = %v, want %v", tc.in, got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Fatalf("splitCSV(%q) = %v, want %v", tc.in, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvKey(t *testing.T) {
+	cases := []struct {
+		in, want string
+	}{
+		{"alerts", "ALERTS"},
+		{"my-topic", "MY_TOPIC"},
+		{"my.topic/v2", "MY_TOPIC_V2"},
+	}
+	for _, tc := range cases {
+		if got := envKey(tc.in); got != tc.want {
+			t.Errorf("envKey(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestLoadRejectsEmptyTopics(t *testing.T) {
+	t.Setenv("TOPICS", ",")
+	t.Setenv("TOPIC", "")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() with TOPICS=\",\" returned no error, want an error instead of panicking on an empty topic list")
+	}
+}
+
+func TestLoadRejectsBlankTopics(t *testing.T) {
+	t.Setenv("TOPICS", "   ")
+	t.Setenv("TOPIC", "")
+
+	if _, err := Load(); err == nil {
+		t.Fatal("Load() with TOPICS=\"   \" returned no error, want an error")
+	}
+}
@@ -0,0 +1,223 @@
+// Package metrics exposes a Prometheus text-format /metrics endpoint and
+// a /healthz liveness probe for ntfy2clip, enabled by setting
+// METRICS_ADDR.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var latencyBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// Registry collects the counters, gauges, and histogram ntfy2clip
+// exposes at /metrics, and the per-topic liveness state backing
+// /healthz.
+type Registry struct {
+	mu      sync.Mutex
+	timeout time.Duration
+
+	messagesReceived map[string]int64
+	clipboardWrites  map[string]int64
+	reconnects       int64
+
+	connected   map[string]bool
+	lastTraffic map[string]time.Time
+	abandoned   map[string]bool
+
+	writeLatencyCount   int64
+	writeLatencySum     float64
+	writeLatencyBuckets map[float64]int64
+}
+
+// New creates a Registry tracking the given topics. timeout is the same
+// per-subscription staleness window RunConnection uses, reused here to
+// decide /healthz liveness.
+func New(topics []string, timeout time.Duration) *Registry {
+	r := &Registry{
+		timeout:             timeout,
+		messagesReceived:    make(map[string]int64),
+		clipboardWrites:     make(map[string]int64),
+		connected:           make(map[string]bool),
+		lastTraffic:         make(map[string]time.Time),
+		abandoned:           make(map[string]bool),
+		writeLatencyBuckets: make(map[float64]int64),
+	}
+	for _, topic := range topics {
+		r.connected[topic] = false
+	}
+	return r
+}
+
+// MessageReceived records a message delivered for topic, regardless of
+// whether it ultimately passed the filter pipeline.
+func (r *Registry) MessageReceived(topic string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messagesReceived[topic]++
+}
+
+// Touch records traffic on topic, resetting the staleness clock used by
+// /healthz and ntfy2clip_seconds_since_last_message.
+func (r *Registry) Touch(topic string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastTraffic[topic] = time.Now()
+}
+
+// SetConnected updates the connection gauge for topic.
+func (r *Registry) SetConnected(topic string, connected bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connected[topic] = connected
+}
+
+// Abandon marks topic as permanently given up on (its circuit breaker
+// tripped), excluding it from the /healthz liveness check so one
+// unrecoverable subscription doesn't force an endless restart loop on
+// an otherwise-healthy process.
+func (r *Registry) Abandon(topic string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.abandoned[topic] = true
+}
+
+// Reconnect records a reconnect attempt across all subscriptions.
+func (r *Registry) Reconnect() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reconnects++
+}
+
+// ClipboardWrite records the outcome and latency of a clipboard.Set call.
+func (r *Registry) ClipboardWrite(status string, latency time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clipboardWrites[status]++
+	r.writeLatencyCount++
+	r.writeLatencySum += latency.Seconds()
+	for _, b := range latencyBuckets {
+		if latency.Seconds() <= b {
+			r.writeLatencyBuckets[b]++
+			break
+		}
+	}
+}
+
+// Healthy reports whether every non-abandoned tracked topic has seen
+// traffic within the configured timeout, for use as a Kubernetes
+// liveness probe. Abandoned topics (their circuit breaker tripped) are
+// excluded, since they're never going to see traffic again and
+// shouldn't force a restart loop for an error that will recur
+// immediately.
+func (r *Registry) Healthy() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for topic := range r.connected {
+		if r.abandoned[topic] {
+			continue
+		}
+		last, seen := r.lastTraffic[topic]
+		if !seen || time.Since(last) >= r.timeout {
+			return false
+		}
+	}
+	return true
+}
+
+// Mux returns the /metrics and /healthz handlers.
+func (r *Registry) Mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", r.serveMetrics)
+	mux.HandleFunc("/healthz", r.serveHealthz)
+	return mux
+}
+
+func (r *Registry) serveHealthz(w http.ResponseWriter, _ *http.Request) {
+	if r.Healthy() {
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "ok\n")
+		return
+	}
+	w.WriteHeader(http.StatusServiceUnavailable)
+	io.WriteString(w, "unhealthy\n")
+}
+
+func (r *Registry) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP ntfy2clip_messages_received_total Messages received per topic.")
+	fmt.Fprintln(w, "# TYPE ntfy2clip_messages_received_total counter")
+	for _, topic := range sortedStringKeys(r.messagesReceived) {
+		fmt.Fprintf(w, "ntfy2clip_messages_received_total{topic=%q} %d\n", topic, r.messagesReceived[topic])
+	}
+
+	fmt.Fprintln(w, "# HELP ntfy2clip_clipboard_writes_total Clipboard writes per status.")
+	fmt.Fprintln(w, "# TYPE ntfy2clip_clipboard_writes_total counter")
+	for _, status := range sortedStringKeys(r.clipboardWrites) {
+		fmt.Fprintf(w, "ntfy2clip_clipboard_writes_total{status=%q} %d\n", status, r.clipboardWrites[status])
+	}
+
+	fmt.Fprintln(w, "# HELP ntfy2clip_reconnects_total Reconnect attempts across all subscriptions.")
+	fmt.Fprintln(w, "# TYPE ntfy2clip_reconnects_total counter")
+	fmt.Fprintf(w, "ntfy2clip_reconnects_total %d\n", r.reconnects)
+
+	fmt.Fprintln(w, "# HELP ntfy2clip_connected Whether a topic's subscription is currently connected.")
+	fmt.Fprintln(w, "# TYPE ntfy2clip_connected gauge")
+	for _, topic := range sortedBoolKeys(r.connected) {
+		v := 0
+		if r.connected[topic] {
+			v = 1
+		}
+		fmt.Fprintf(w, "ntfy2clip_connected{topic=%q} %d\n", topic, v)
+	}
+
+	fmt.Fprintln(w, "# HELP ntfy2clip_seconds_since_last_message Seconds since the last traffic on a topic.")
+	fmt.Fprintln(w, "# TYPE ntfy2clip_seconds_since_last_message gauge")
+	for _, topic := range sortedBoolKeys(r.connected) {
+		seconds := 0.0
+		if last, seen := r.lastTraffic[topic]; seen {
+			seconds = time.Since(last).Seconds()
+		}
+		fmt.Fprintf(w, "ntfy2clip_seconds_since_last_message{topic=%q} %f\n", topic, seconds)
+	}
+
+	fmt.Fprintln(w, "# HELP ntfy2clip_clipboard_write_latency_seconds Clipboard write latency.")
+	fmt.Fprintln(w, "# TYPE ntfy2clip_clipboard_write_latency_seconds histogram")
+	var cumulative int64
+	for _, b := range latencyBuckets {
+		cumulative += r.writeLatencyBuckets[b]
+		fmt.Fprintf(w, "ntfy2clip_clipboard_write_latency_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(b, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "ntfy2clip_clipboard_write_latency_seconds_bucket{le=\"+Inf\"} %d\n", r.writeLatencyCount)
+	fmt.Fprintf(w, "ntfy2clip_clipboard_write_latency_seconds_sum %f\n", r.writeLatencySum)
+	fmt.Fprintf(w, "ntfy2clip_clipboard_write_latency_seconds_count %d\n", r.writeLatencyCount)
+}
+
+func sortedStringKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedBoolKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
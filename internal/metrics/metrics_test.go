@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthyRequiresTrafficOnEveryTopic(t *testing.T) {
+	r := New([]string{"a", "b"}, time.Minute)
+
+	if r.Healthy() {
+		t.Fatal("Healthy() = true before any topic has seen traffic, want false")
+	}
+
+	r.Touch("a")
+	r.Touch("b")
+	if !r.Healthy() {
+		t.Fatal("Healthy() = false after every topic saw traffic, want true")
+	}
+}
+
+func TestHealthyExcludesAbandonedTopics(t *testing.T) {
+	r := New([]string{"a", "b"}, time.Minute)
+	r.Touch("a")
+	r.Abandon("b")
+
+	if !r.Healthy() {
+		t.Fatal("Healthy() = false with one abandoned topic and the rest healthy, want true")
+	}
+}
+
+func TestHealthyStaleTraffic(t *testing.T) {
+	r := New([]string{"a"}, time.Millisecond)
+	r.Touch("a")
+	time.Sleep(5 * time.Millisecond)
+
+	if r.Healthy() {
+		t.Fatal("Healthy() = true with traffic older than the timeout, want false")
+	}
+}
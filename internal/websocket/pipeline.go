@@ -0,0 +1,57 @@
+package websocket
+
+import (
+	"bytes"
+
+	"github.com/lucifer/ntfy2clip/internal/config"
+)
+
+// shouldCopy reports whether msg passes the configured PRIORITY_MIN,
+// TAG_FILTER, and TITLE_REGEX filters.
+func shouldCopy(cfg *config.Config, msg *ntfyMessage) bool {
+	priority := msg.Priority
+	if priority == 0 {
+		priority = defaultNtfyPriority
+	}
+	if priority < cfg.PriorityMin {
+		return false
+	}
+
+	for _, tag := range cfg.TagFilter {
+		if !hasTag(msg.Tags, tag) {
+			return false
+		}
+	}
+
+	if cfg.TitleRegex != nil && !cfg.TitleRegex.MatchString(msg.Title) {
+		return false
+	}
+
+	return true
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// renderContent produces the clipboard content for msg: the output of
+// cfg.Template if one is configured, otherwise the raw message body.
+func renderContent(cfg *config.Config, msg *ntfyMessage) (string, error) {
+	if cfg.Template == nil {
+		if msg.Message == nil {
+			return "", nil
+		}
+		return *msg.Message, nil
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.Template.Execute(&buf, msg); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
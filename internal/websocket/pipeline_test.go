@@ -0,0 +1,130 @@
+package websocket
+
+import (
+	"regexp"
+	"testing"
+	"text/template"
+
+	"github.com/lucifer/ntfy2clip/internal/config"
+)
+
+func TestShouldCopy(t *testing.T) {
+	titleRegex := regexp.MustCompile(`^Alert`)
+
+	cases := []struct {
+		name string
+		cfg  *config.Config
+		msg  *ntfyMessage
+		want bool
+	}{
+		{
+			name: "no filters",
+			cfg:  &config.Config{},
+			msg:  &ntfyMessage{},
+			want: true,
+		},
+		{
+			name: "below PRIORITY_MIN",
+			cfg:  &config.Config{PriorityMin: 4},
+			msg:  &ntfyMessage{Priority: 3},
+			want: false,
+		},
+		{
+			name: "default priority meets PRIORITY_MIN",
+			cfg:  &config.Config{PriorityMin: 3},
+			msg:  &ntfyMessage{},
+			want: true,
+		},
+		{
+			name: "missing required tag",
+			cfg:  &config.Config{TagFilter: []string{"urgent"}},
+			msg:  &ntfyMessage{Tags: []string{"other"}},
+			want: false,
+		},
+		{
+			name: "has required tag",
+			cfg:  &config.Config{TagFilter: []string{"urgent"}},
+			msg:  &ntfyMessage{Tags: []string{"other", "urgent"}},
+			want: true,
+		},
+		{
+			name: "title doesn't match TITLE_REGEX",
+			cfg:  &config.Config{TitleRegex: titleRegex},
+			msg:  &ntfyMessage{Title: "Reminder"},
+			want: false,
+		},
+		{
+			name: "title matches TITLE_REGEX",
+			cfg:  &config.Config{TitleRegex: titleRegex},
+			msg:  &ntfyMessage{Title: "Alert: disk full"},
+			want: true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := shouldCopy(tc.cfg, tc.msg); got != tc.want {
+				t.Errorf("shouldCopy() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRenderContentWithoutTemplate(t *testing.T) {
+	body := "hello world"
+	got, err := renderContent(&config.Config{}, &ntfyMessage{Message: &body})
+	if err != nil {
+		t.Fatalf("renderContent() error = %v", err)
+	}
+	if got != body {
+		t.Errorf("renderContent() = %q, want %q", got, body)
+	}
+}
+
+func TestRenderContentWithoutTemplateNilMessage(t *testing.T) {
+	got, err := renderContent(&config.Config{}, &ntfyMessage{})
+	if err != nil {
+		t.Fatalf("renderContent() error = %v", err)
+	}
+	if got != "" {
+		t.Errorf("renderContent() = %q, want empty string", got)
+	}
+}
+
+func TestRenderContentWithTemplate(t *testing.T) {
+	tmpl, err := template.New("TEMPLATE").Parse("[{{.Title}}] {{.Message}}")
+	if err != nil {
+		t.Fatalf("template.Parse() error = %v", err)
+	}
+	body := "disk at 95%"
+	cfg := &config.Config{Template: tmpl}
+	msg := &ntfyMessage{Title: "Alert", Message: &body}
+
+	got, err := renderContent(cfg, msg)
+	if err != nil {
+		t.Fatalf("renderContent() error = %v", err)
+	}
+	want := "[Alert] disk at 95%"
+	if got != want {
+		t.Errorf("renderContent() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderContentAttachmentFieldOnMessageWithoutOne(t *testing.T) {
+	tmpl, err := template.New("TEMPLATE").Parse("{{.Message}} ({{.Attachment.URL}})")
+	if err != nil {
+		t.Fatalf("template.Parse() error = %v", err)
+	}
+	body := "no attachment here"
+	cfg := &config.Config{Template: tmpl}
+	msg := &ntfyMessage{Message: &body}
+
+	got, err := renderContent(cfg, msg)
+	if err != nil {
+		t.Fatalf("renderContent() error = %v, want nil even though msg.Attachment is unset", err)
+	}
+	want := "no attachment here ()"
+	if got != want {
+		t.Errorf("renderContent() = %q, want %q", got, want)
+	}
+}
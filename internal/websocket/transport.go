@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lucifer/ntfy2clip/internal/config"
+)
+
+// Transport names accepted by the TRANSPORT env var / config.Config.Transport.
+const (
+	TransportWS   = "ws"
+	TransportJSON = "json"
+	TransportSSE  = "sse"
+)
+
+// ntfyMessage is the ntfy message envelope, shared by every transport.
+// Fields beyond Event/Topic/Message are exported so they're addressable
+// from a user-supplied TEMPLATE. Attachment is a value, not a pointer,
+// so a TEMPLATE referencing e.g. "{{.Attachment.URL}}" degrades to an
+// empty string on messages without one instead of making every
+// Execute call on an attachment-less message error out.
+type ntfyMessage struct {
+	Event      string         `json:"event"`
+	Topic      string         `json:"topic"`
+	Message    *string        `json:"message,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Priority   int            `json:"priority,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Click      string         `json:"click,omitempty"`
+	Attachment ntfyAttachment `json:"attachment,omitempty"`
+	Actions    []ntfyAction   `json:"actions,omitempty"`
+}
+
+type ntfyAttachment struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+	Type string `json:"type,omitempty"`
+	Size int64  `json:"size,omitempty"`
+}
+
+type ntfyAction struct {
+	Action string `json:"action,omitempty"`
+	Label  string `json:"label,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// defaultNtfyPriority is the priority ntfy assigns a message when the
+// publisher omits the field.
+const defaultNtfyPriority = 3
+
+// Event is delivered on a transport's subscription channel: either a
+// parsed message, or a terminal error/close that ends the subscription.
+type Event struct {
+	Message *ntfyMessage
+	Err     error
+}
+
+// Transport subscribes to a topic on an ntfy server and streams parsed
+// messages back on the returned channel until ctx is cancelled or a
+// terminal error occurs.
+type Transport interface {
+	Subscribe(ctx context.Context, cfg *config.Config) (<-chan Event, error)
+}
+
+func newTransport(name string) Transport {
+	switch name {
+	case TransportJSON:
+		return jsonStreamTransport{}
+	case TransportSSE:
+		return sseTransport{}
+	default:
+		return wsTransport{}
+	}
+}
+
+// UpgradeError reports that a transport's subscribe attempt was
+// rejected with an HTTP status instead of succeeding, so callers can
+// tell a 401/404 (won't fix itself) apart from a network failure.
+type UpgradeError struct {
+	StatusCode int
+}
+
+func (e *UpgradeError) Error() string {
+	return fmt.Sprintf("got HTTP %d instead of a successful subscription", e.StatusCode)
+}
+
+// IsFatal reports whether err is an UpgradeError in the 4xx range
+// (auth/topic errors ntfy returns instead of a successful subscription)
+// as opposed to a network-level failure that's worth retrying.
+func IsFatal(err error) bool {
+	var ue *UpgradeError
+	if !errors.As(err, &ue) {
+		return false
+	}
+	return ue.StatusCode >= 400 && ue.StatusCode < 500
+}
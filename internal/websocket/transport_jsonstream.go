@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/lucifer/ntfy2clip/internal/config"
+)
+
+// jsonStreamTransport subscribes via ntfy's chunked HTTP JSON stream
+// (/topic/json), one JSON message per line. It works behind proxies
+// that break long-lived WebSocket upgrades but happily forward chunked
+// HTTP responses.
+type jsonStreamTransport struct{}
+
+func (jsonStreamTransport) Subscribe(ctx context.Context, cfg *config.Config) (<-chan Event, error) {
+	url := fmt.Sprintf("%s://%s/%s/json", httpScheme(cfg.Scheme), cfg.Server, cfg.Topic)
+
+	resp, err := httpGet(ctx, url, cfg.Token)
+	if err != nil {
+		return nil, fmt.Errorf("json-stream subscribe error: %w", err)
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			if len(line) == 0 {
+				continue
+			}
+
+			var msg ntfyMessage
+			if err := json.Unmarshal(line, &msg); err != nil {
+				log.Printf("Error parsing JSON: %v", err)
+				continue
+			}
+
+			select {
+			case events <- Event{Message: &msg}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		err := scanner.Err()
+		if err == nil {
+			err = fmt.Errorf("json stream closed")
+		}
+		select {
+		case events <- Event{Err: err}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return events, nil
+}
@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/lucifer/ntfy2clip/internal/config"
+)
+
+// sseTransport subscribes via ntfy's Server-Sent Events endpoint
+// (/topic/sse), decoding the "data:" field of each event as a message.
+type sseTransport struct{}
+
+func (sseTransport) Subscribe(ctx context.Context, cfg *config.Config) (<-chan Event, error) {
+	url := fmt.Sprintf("%s://%s/%s/sse", httpScheme(cfg.Scheme), cfg.Server, cfg.Topic)
+
+	resp, err := httpGet(ctx, url, cfg.Token)
+	if err != nil {
+		return nil, fmt.Errorf("sse subscribe error: %w", err)
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			data = strings.TrimSpace(data)
+			if data == "" {
+				continue
+			}
+
+			var msg ntfyMessage
+			if err := json.Unmarshal([]byte(data), &msg); err != nil {
+				log.Printf("Error parsing JSON: %v", err)
+				continue
+			}
+
+			select {
+			case events <- Event{Message: &msg}:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		err := scanner.Err()
+		if err == nil {
+			err = fmt.Errorf("sse stream closed")
+		}
+		select {
+		case events <- Event{Err: err}:
+		case <-ctx.Done():
+		}
+	}()
+
+	return events, nil
+}
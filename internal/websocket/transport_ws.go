@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/lucifer/ntfy2clip/internal/config"
+)
+
+// wsTransport subscribes via ntfy's native WebSocket endpoint
+// (/topic/ws). It's the default and lowest-latency transport.
+type wsTransport struct{}
+
+func (wsTransport) Subscribe(ctx context.Context, cfg *config.Config) (<-chan Event, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	conn, err := dial(dialCtx, cfg)
+	cancel()
+
+	if err != nil {
+		return nil, fmt.Errorf("dial error: %w", err)
+	}
+
+	events := make(chan Event)
+
+	go func() {
+		defer close(events)
+		defer conn.Close(websocket.StatusNormalClosure, "bye")
+
+		for {
+			msgType, data, err := conn.Read(ctx)
+			if err != nil {
+				select {
+				case events <- Event{Err: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			if msgType != websocket.MessageText {
+				continue
+			}
+
+			var msg ntfyMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				log.Printf("Error parsing JSON: %v", err)
+				continue
+			}
+
+			select {
+			case events <- Event{Message: &msg}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func dial(ctx context.Context, cfg *config.Config) (*websocket.Conn, error) {
+	url := fmt.Sprintf("%s://%s/%s/ws", cfg.Scheme, cfg.Server, cfg.Topic)
+
+	headers := http.Header{}
+	if cfg.Token != "" {
+		headers.Set("Authorization", "Bearer "+cfg.Token)
+	}
+
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		},
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+		}).DialContext,
+	}
+
+	opts := &websocket.DialOptions{
+		HTTPHeader: headers,
+		HTTPClient: &http.Client{Transport: transport},
+	}
+
+	conn, resp, err := websocket.Dial(ctx, url, opts)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("%w (%v)", &UpgradeError{StatusCode: resp.StatusCode}, err)
+		}
+		return nil, err
+	}
+	return conn, nil
+}
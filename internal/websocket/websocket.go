@@ -2,61 +2,43 @@ package websocket
 
 import (
 	"context"
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
+	"io"
 	"log"
-	"net"
 	"net/http"
 	"time"
 
-	"github.com/coder/websocket"
 	"github.com/lucifer/ntfy2clip/internal/clipboard"
 	"github.com/lucifer/ntfy2clip/internal/config"
+	"github.com/lucifer/ntfy2clip/internal/metrics"
 )
 
-type ntfyMessage struct {
-	Event   string  `json:"event"`
-	Topic   string  `json:"topic"`
-	Message *string `json:"message,omitempty"`
-}
+// maxAttachmentBytes caps how much of an ntfy attachment we'll pull into
+// memory for a clipboard write.
+const maxAttachmentBytes = 25 << 20 // 25 MiB
 
-func dial(ctx context.Context, cfg *config.Config) (*websocket.Conn, error) {
-	url := fmt.Sprintf("%s://%s/%s/ws", cfg.Scheme, cfg.Server, cfg.Topic)
+// attachmentFetchTimeout bounds a single attachment download so a slow or
+// unresponsive host can't block the connection's goroutine indefinitely.
+const attachmentFetchTimeout = 30 * time.Second
 
-	headers := http.Header{}
-	if cfg.Token != "" {
-		headers.Set("Authorization", "Bearer "+cfg.Token)
-	}
+func RunConnection(ctx context.Context, cfg *config.Config, reg *metrics.Registry) error {
+	t := newTransport(cfg.Transport)
 
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		},
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout: 10 * time.Second,
-		}).DialContext,
+	events, err := t.Subscribe(ctx, cfg)
+	if err != nil && cfg.Transport == TransportWS && IsFatal(err) {
+		log.Printf("WebSocket upgrade failed (%v), falling back to JSON-stream transport", err)
+		t = jsonStreamTransport{}
+		events, err = t.Subscribe(ctx, cfg)
 	}
-
-	opts := &websocket.DialOptions{
-		HTTPHeader: headers,
-		HTTPClient: &http.Client{Transport: transport},
+	if err != nil {
+		return fmt.Errorf("subscribe error: %w", err)
 	}
 
-	conn, _, err := websocket.Dial(ctx, url, opts)
-	return conn, err
-}
-
-func RunConnection(ctx context.Context, cfg *config.Config) error {
-	dialCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
-	conn, err := dial(dialCtx, cfg)
-	cancel()
-
-	if err != nil {
-		return fmt.Errorf("dial error: %w", err)
+	if reg != nil {
+		reg.SetConnected(cfg.Topic, true)
+		reg.Touch(cfg.Topic)
+		defer reg.SetConnected(cfg.Topic, false)
 	}
-	defer conn.Close(websocket.StatusNormalClosure, "bye")
 
 	log.Printf("Connected to %s with topic=%s and timeout=%v", cfg.Server, cfg.Topic, cfg.Timeout)
 
@@ -64,50 +46,24 @@ func RunConnection(ctx context.Context, cfg *config.Config) error {
 	ticker := time.NewTicker(cfg.Timeout)
 	defer ticker.Stop()
 
-	readCh := make(chan readResult)
-
-	go func() {
-		for {
-			msgType, data, err := conn.Read(ctx)
-			select {
-			case readCh <- readResult{msgType, data, err}:
-			case <-ctx.Done():
-				return
-			}
-			if err != nil {
-				return
-			}
-		}
-	}()
-
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 
-		case result := <-readCh:
-			lastTraffic = time.Now()
-
-			if result.err != nil {
-				return fmt.Errorf("read error: %w", result.err)
+		case ev, ok := <-events:
+			if !ok {
+				return fmt.Errorf("subscription closed")
+			}
+			if ev.Err != nil {
+				return fmt.Errorf("read error: %w", ev.Err)
 			}
 
-			if result.msgType == websocket.MessageText {
-				var msg ntfyMessage
-				if err := json.Unmarshal(result.data, &msg); err != nil {
-					log.Printf("Error parsing JSON: %v", err)
-					continue
-				}
-
-				if msg.Topic == cfg.Topic && msg.Event == "message" && msg.Message != nil {
-					log.Printf("WS received message: event=%s, topic=%s", msg.Event, msg.Topic)
-					go func(content string) {
-						if err := clipboard.Set(content); err != nil {
-							log.Printf("Failed to set clipboard: %v", err)
-						}
-					}(*msg.Message)
-				}
+			lastTraffic = time.Now()
+			if reg != nil {
+				reg.Touch(cfg.Topic)
 			}
+			handleMessage(ctx, cfg, ev.Message, reg)
 
 		case <-ticker.C:
 			if time.Since(lastTraffic) > cfg.Timeout {
@@ -117,8 +73,112 @@ func RunConnection(ctx context.Context, cfg *config.Config) error {
 	}
 }
 
-type readResult struct {
-	msgType websocket.MessageType
-	data    []byte
-	err     error
+func handleMessage(ctx context.Context, cfg *config.Config, msg *ntfyMessage, reg *metrics.Registry) {
+	if msg == nil || msg.Topic != cfg.Topic || msg.Event != "message" {
+		return
+	}
+
+	if reg != nil {
+		reg.MessageReceived(msg.Topic)
+	}
+
+	if !shouldCopy(cfg, msg) {
+		return
+	}
+
+	// With no TEMPLATE to apply, an attachment (e.g. an image ntfy
+	// received) takes priority over the text body, so it lands on the
+	// clipboard as its native MIME type rather than just its URL as text.
+	if cfg.Template == nil && msg.Attachment.URL != "" {
+		log.Printf("Received message: event=%s, topic=%s, attachment=%s", msg.Event, msg.Topic, msg.Attachment.URL)
+		go copyAttachment(ctx, msg.Attachment, reg)
+		return
+	}
+
+	content, err := renderContent(cfg, msg)
+	if err != nil {
+		log.Printf("Error rendering template: %v", err)
+		return
+	}
+	if content == "" {
+		return
+	}
+
+	if cfg.PrefixClipboardWithTopic {
+		content = fmt.Sprintf("[%s] %s", msg.Topic, content)
+	}
+
+	log.Printf("Received message: event=%s, topic=%s", msg.Event, msg.Topic)
+	go func(content string) {
+		start := time.Now()
+		err := clipboard.Set(clipboard.Text(content))
+
+		if reg != nil {
+			status := "ok"
+			if err != nil {
+				status = "error"
+			}
+			reg.ClipboardWrite(status, time.Since(start))
+		}
+
+		if err != nil {
+			log.Printf("Failed to set clipboard: %v", err)
+		}
+	}(content)
+}
+
+// copyAttachment downloads att and copies it to the clipboard under its
+// native MIME type.
+func copyAttachment(ctx context.Context, att ntfyAttachment, reg *metrics.Registry) {
+	start := time.Now()
+	data, mimeType, err := fetchAttachment(ctx, att)
+	if err == nil {
+		err = clipboard.Set(clipboard.Content{MIMEType: mimeType, Data: data})
+	}
+
+	if reg != nil {
+		status := "ok"
+		if err != nil {
+			status = "error"
+		}
+		reg.ClipboardWrite(status, time.Since(start))
+	}
+
+	if err != nil {
+		log.Printf("Failed to copy attachment: %v", err)
+	}
+}
+
+// fetchAttachment downloads att.URL, capped at maxAttachmentBytes, and
+// returns its bytes along with the MIME type to copy it under
+// (att.Type if ntfy reported one, otherwise the response's Content-Type).
+func fetchAttachment(ctx context.Context, att ntfyAttachment) ([]byte, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, attachmentFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, att.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, err := newHTTPClient().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("got HTTP %d fetching attachment", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAttachmentBytes))
+	if err != nil {
+		return nil, "", err
+	}
+
+	mimeType := att.Type
+	if mimeType == "" {
+		mimeType = resp.Header.Get("Content-Type")
+	}
+	return data, mimeType, nil
 }
@@ -0,0 +1,54 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchAttachmentUsesReportedType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte("png-bytes"))
+	}))
+	defer srv.Close()
+
+	data, mimeType, err := fetchAttachment(context.Background(), ntfyAttachment{URL: srv.URL, Type: "image/png"})
+	if err != nil {
+		t.Fatalf("fetchAttachment() error = %v", err)
+	}
+	if string(data) != "png-bytes" {
+		t.Errorf("fetchAttachment() data = %q, want %q", data, "png-bytes")
+	}
+	if mimeType != "image/png" {
+		t.Errorf("fetchAttachment() mimeType = %q, want %q (att.Type should win over Content-Type)", mimeType, "image/png")
+	}
+}
+
+func TestFetchAttachmentFallsBackToContentType(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/jpeg")
+		w.Write([]byte("jpeg-bytes"))
+	}))
+	defer srv.Close()
+
+	_, mimeType, err := fetchAttachment(context.Background(), ntfyAttachment{URL: srv.URL})
+	if err != nil {
+		t.Fatalf("fetchAttachment() error = %v", err)
+	}
+	if mimeType != "image/jpeg" {
+		t.Errorf("fetchAttachment() mimeType = %q, want %q", mimeType, "image/jpeg")
+	}
+}
+
+func TestFetchAttachmentNonOKStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, _, err := fetchAttachment(context.Background(), ntfyAttachment{URL: srv.URL}); err == nil {
+		t.Fatal("fetchAttachment() error = nil for a 404 response, want an error")
+	}
+}